@@ -7,6 +7,12 @@ import (
 
 type Config struct {
 	Port int
+
+	// ParallelThreshold is the subtree size above which SMT construction
+	// parallelizes; 0 means "use the accumulator's default". DisableParallel
+	// forces sequential construction regardless of threshold.
+	ParallelThreshold int
+	DisableParallel   bool
 }
 
 func LoadConfig() *Config {
@@ -16,6 +22,25 @@ func LoadConfig() *Config {
 			port = p
 		}
 	}
-	return &Config{Port: port}
+
+	parallelThreshold := 0
+	if thresholdStr := os.Getenv("PARALLEL_THRESHOLD"); thresholdStr != "" {
+		if t, err := strconv.Atoi(thresholdStr); err == nil {
+			parallelThreshold = t
+		}
+	}
+
+	disableParallel := false
+	if disableStr := os.Getenv("DISABLE_PARALLEL"); disableStr != "" {
+		if d, err := strconv.ParseBool(disableStr); err == nil {
+			disableParallel = d
+		}
+	}
+
+	return &Config{
+		Port:              port,
+		ParallelThreshold: parallelThreshold,
+		DisableParallel:   disableParallel,
+	}
 }
 
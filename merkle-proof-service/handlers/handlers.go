@@ -1,11 +1,12 @@
 package handlers
 
 import (
+	"encoding/hex"
+	"math/big"
 	"net/http"
 
 	"merkle-proof-service/service"
 
-	"github.com/CycloneDX/cyclonedx-go"
 	"github.com/gin-gonic/gin"
 )
 
@@ -22,13 +23,20 @@ func (h *Handler) Health(c *gin.Context) {
 }
 
 func (h *Handler) Build(c *gin.Context) {
-	var bom cyclonedx.BOM
-	if err := c.ShouldBindJSON(&bom); err != nil {
+	var req BuildRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	result, err := h.svc.BuildSMT(&bom, "dependency", "smt")
+	if req.Extractor == "" {
+		req.Extractor = "dependency"
+	}
+	if req.Accumulator == "" {
+		req.Accumulator = "smt"
+	}
+
+	result, err := h.svc.BuildSMT(&req.SBOM, req.Extractor, req.Accumulator, req.Hash)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
@@ -63,6 +71,11 @@ func (h *Handler) ProveBatch(c *gin.Context) {
 		req.Accumulator = "smt"
 	}
 
+	if req.Accumulator == "bmt" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "bmt accumulator is not addressable by bare purl; /prove-batch does not support it yet"})
+		return
+	}
+
 	if len(req.PURLs) == 0 {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "no purls"})
 		return
@@ -97,3 +110,101 @@ func (h *Handler) ProveBatch(c *gin.Context) {
 	})
 }
 
+func (h *Handler) VerifyBatch(c *gin.Context) {
+	var req VerifyBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if req.Accumulator == "" {
+		req.Accumulator = "smt"
+	}
+
+	if req.Accumulator == "bmt" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "verification is not supported for the bmt accumulator yet"})
+		return
+	}
+
+	expectedRoot, err := hex.DecodeString(req.ExpectedRoot)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid expected_root"})
+		return
+	}
+
+	verifier := service.NewSMTVerifier()
+	results := make([]VerifyResult, len(req.MerkleProofs))
+
+	for i, p := range req.MerkleProofs {
+		value, ok := new(big.Int).SetString(p.Value, 10)
+		if !ok {
+			results[i] = VerifyResult{Purl: p.Purl, Error: "invalid value"}
+			continue
+		}
+
+		siblings := make([][]byte, len(p.Siblings))
+		invalid := false
+		for j, sibHex := range p.Siblings {
+			sib, err := hex.DecodeString(sibHex)
+			if err != nil {
+				results[i] = VerifyResult{Purl: p.Purl, Error: "invalid sibling"}
+				invalid = true
+				break
+			}
+			siblings[j] = sib
+		}
+		if invalid {
+			continue
+		}
+
+		var bitmap []byte
+		if p.Bitmap != "" {
+			bitmap, err = hex.DecodeString(p.Bitmap)
+			if err != nil {
+				results[i] = VerifyResult{Purl: p.Purl, Error: "invalid bitmap"}
+				continue
+			}
+		}
+
+		valid, err := verifier.Verify(expectedRoot, p.Purl, value, siblings, bitmap, req.Depth, req.Hash)
+		if err != nil {
+			results[i] = VerifyResult{Purl: p.Purl, Error: err.Error()}
+			continue
+		}
+
+		results[i] = VerifyResult{Purl: p.Purl, Valid: valid}
+	}
+
+	c.JSON(http.StatusOK, VerifyBatchResponse{Root: req.ExpectedRoot, Results: results})
+}
+
+func (h *Handler) UpdateSMT(c *gin.Context) {
+	var req UpdateSMTRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if req.Accumulator == "" {
+		req.Accumulator = "smt"
+	}
+
+	if req.Accumulator == "bmt" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "bmt accumulator is not addressable by bare purl; /update-smt does not support it yet"})
+		return
+	}
+
+	inserts := make([]service.InsertOp, len(req.Inserts))
+	for i, ins := range req.Inserts {
+		inserts[i] = service.InsertOp{Purl: ins.Purl, Value: big.NewInt(ins.Value)}
+	}
+
+	result, err := h.svc.UpdateSMT(req.Root, inserts, req.Deletes, req.Accumulator)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, UpdateSMTResponse{Root: result.Root, Depth: result.Depth})
+}
+
@@ -10,6 +10,7 @@ type BuildRequest struct {
 	SBOM        cyclonedx.BOM `json:"sbom"`
 	Extractor   string        `json:"extractor"`
 	Accumulator string        `json:"accumulator"`
+	Hash        string        `json:"hash"`
 }
 
 type BuildResponse struct {
@@ -19,10 +20,10 @@ type BuildResponse struct {
 }
 
 type ProveBatchRequest struct {
-	SMT         json.RawMessage `json:"smt" binding:"required"`
-	PURLs       []string        `json:"purls" binding:"required"`
-	Compress    bool            `json:"compress"`
-	Accumulator string          `json:"accumulator"`
+	Root        string   `json:"root" binding:"required"`
+	PURLs       []string `json:"purls" binding:"required"`
+	Compress    bool     `json:"compress"`
+	Accumulator string   `json:"accumulator"`
 }
 
 type ProofOutput struct {
@@ -39,6 +40,43 @@ type ProveBatchResponse struct {
 	MerkleProofs []ProofOutput `json:"merkle_proofs"`
 }
 
+type VerifyBatchRequest struct {
+	Depth        int           `json:"depth" binding:"required"`
+	Root         string        `json:"root"`
+	ExpectedRoot string        `json:"expected_root" binding:"required"`
+	MerkleProofs []ProofOutput `json:"merkle_proofs" binding:"required"`
+	Hash         string        `json:"hash"`
+	Accumulator  string        `json:"accumulator"`
+}
+
+type VerifyResult struct {
+	Purl  string `json:"purl"`
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+type VerifyBatchResponse struct {
+	Root    string         `json:"root"`
+	Results []VerifyResult `json:"results"`
+}
+
+type InsertInput struct {
+	Purl  string `json:"purl" binding:"required"`
+	Value int64  `json:"value"`
+}
+
+type UpdateSMTRequest struct {
+	Root        string        `json:"root" binding:"required"`
+	Inserts     []InsertInput `json:"inserts"`
+	Deletes     []string      `json:"deletes"`
+	Accumulator string        `json:"accumulator"`
+}
+
+type UpdateSMTResponse struct {
+	Root  string `json:"root"`
+	Depth int    `json:"depth"`
+}
+
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
@@ -29,6 +29,8 @@ func main() {
 	defer storage.Close()
 
 	svc := service.NewSMTService(storage)
+	svc.DisableParallel = config.DisableParallel
+	svc.ParallelThreshold = config.ParallelThreshold
 	h := handlers.NewHandler(svc)
 
 	router := gin.New()
@@ -37,8 +39,9 @@ func main() {
 	router.GET("/health", h.Health)
 	router.GET("/smt/:root", h.GetSMT)
 	router.POST("/build", h.Build)
-	router.POST("/store-smt", h.StoreSMT)
 	router.POST("/prove-batch", h.ProveBatch)
+	router.POST("/verify-batch", h.VerifyBatch)
+	router.POST("/update-smt", h.UpdateSMT)
 
 	srv := &http.Server{
 		Addr:    fmt.Sprintf(":%d", config.Port),
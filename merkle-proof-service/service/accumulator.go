@@ -9,14 +9,22 @@ import (
 type accumulator interface {
 	Build(items map[string]*big.Int) ([]byte, error)
 	GenerateProof(preImage string) ([][]byte, *big.Int, error)
+	Insert(preImage string, value *big.Int) ([]byte, error)
+	Delete(preImage string) ([]byte, error)
 	json.Marshaler
 	json.Unmarshaler
 }
 
-func getAccumulator(name string) (accumulator, error) {
+func getAccumulator(name, hashName string) (accumulator, error) {
 	switch name {
 	case "smt":
-		return newSMT(256)
+		hasher, err := getHasher(hashName)
+		if err != nil {
+			return nil, err
+		}
+		return newSMTWithHasher(256, hasher)
+	case "bmt":
+		return newBMT()
 	default:
 		return nil, fmt.Errorf("unknown accumulator: %s", name)
 	}
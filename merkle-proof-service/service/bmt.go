@@ -0,0 +1,456 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Swarm-style chunking constants: a chunk is a fixed number of fixed-size
+// segments, and files spanning more than one chunk chain the chunk roots
+// into a further tree so the root is bound to the full file size.
+const (
+	defaultSegmentSize      = 32
+	defaultSegmentsPerChunk = 128
+	defaultChunkSize        = defaultSegmentSize * defaultSegmentsPerChunk
+)
+
+// bmtKeySep separates the purl from the byte offset in a BMT preimage. A
+// NUL byte can't appear in a valid PURL, so it's safe as a delimiter.
+const bmtKeySep = "\x00"
+
+func bmtKey(purl string, offset int64) string {
+	return purl + bmtKeySep + strconv.FormatInt(offset, 10)
+}
+
+func parseBMTKey(key string) (purl string, offset int64, err error) {
+	idx := strings.LastIndex(key, bmtKeySep)
+	if idx < 0 {
+		return "", 0, fmt.Errorf("invalid bmt key: %s", key)
+	}
+
+	offset, err = strconv.ParseInt(key[idx+len(bmtKeySep):], 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid bmt key: %s", key)
+	}
+
+	return key[:idx], offset, nil
+}
+
+type offsetValue struct {
+	offset int64
+	value  *big.Int
+}
+
+// BMT is a Swarm-style Binary Merkle Tree accumulator: each item is a
+// (purl, offset) -> chunkHash entry, so a proof can attest to a specific
+// byte range of a specific artifact instead of just the artifact's
+// presence. Per purl, segments are grouped into fixed-size chunks and
+// chunk roots are chained into a file root; file roots across all purls
+// are then combined into the accumulator root.
+//
+// Unlike SMT, BMT is a dense tree: Insert/Delete recompute the whole
+// commitment rather than patching a single path.
+type BMT struct {
+	segmentSize      int
+	segmentsPerChunk int
+	root             []byte
+
+	// depth is the height of the deepest GenerateProof path currently
+	// possible: the file-root combination depth plus the tallest purl's
+	// chunk-chain depth. Unlike SMT's fixed depth, it varies with the
+	// items inserted and isn't the same for every proof.
+	depth int
+
+	items map[string]*big.Int
+}
+
+func newBMT() (*BMT, error) {
+	return &BMT{
+		segmentSize:      defaultSegmentSize,
+		segmentsPerChunk: defaultSegmentsPerChunk,
+		items:            make(map[string]*big.Int),
+	}, nil
+}
+
+func (b *BMT) Build(items map[string]*big.Int) ([]byte, error) {
+	b.items = make(map[string]*big.Int, len(items))
+	for k, v := range items {
+		b.items[k] = v
+	}
+	return b.rebuild()
+}
+
+func (b *BMT) Insert(preImage string, value *big.Int) ([]byte, error) {
+	if _, _, err := parseBMTKey(preImage); err != nil {
+		return nil, err
+	}
+	b.items[preImage] = value
+	return b.rebuild()
+}
+
+func (b *BMT) Delete(preImage string) ([]byte, error) {
+	delete(b.items, preImage)
+	return b.rebuild()
+}
+
+func (b *BMT) rebuild() ([]byte, error) {
+	byPurl, err := b.groupByPurl()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(byPurl) == 0 {
+		b.root = make([]byte, b.segmentSize)
+		b.depth = 0
+		return b.root, nil
+	}
+
+	purls := sortedPurls(byPurl)
+	fileRoots := make([][]byte, len(purls))
+	maxChunkDepth := 0
+	for i, purl := range purls {
+		segments, err := b.segmentsForPurl(byPurl[purl])
+		if err != nil {
+			return nil, err
+		}
+		fileRoots[i] = b.chainedChunkRoot(segments)
+		if d := b.chunkChainDepth(len(segments)); d > maxChunkDepth {
+			maxChunkDepth = d
+		}
+	}
+
+	b.root = merkleRoot(fileRoots, b.segmentSize)
+	b.depth = maxChunkDepth + treeDepth(len(purls))
+	return b.root, nil
+}
+
+// chunkChainDepth returns the height of chainedChunkRoot's tree for a purl
+// with numSegments segments: the number of sibling hashes the deepest
+// GenerateProof call for that purl contributes before reaching its file
+// root.
+func (b *BMT) chunkChainDepth(numSegments int) int {
+	if numSegments <= 1 {
+		return treeDepth(numSegments)
+	}
+
+	depth := 0
+	level := numSegments
+	for {
+		numChunks := (level + b.segmentsPerChunk - 1) / b.segmentsPerChunk
+		chunkSize := level
+		if numChunks > 1 {
+			chunkSize = b.segmentsPerChunk
+		}
+		depth += treeDepth(chunkSize)
+		if numChunks <= 1 {
+			return depth
+		}
+		level = numChunks
+	}
+}
+
+// treeDepth returns ceil(log2(n)) for n >= 1, i.e. the height of a
+// padToPow2'd binary tree over n leaves.
+func treeDepth(n int) int {
+	depth := 0
+	size := 1
+	for size < n {
+		size *= 2
+		depth++
+	}
+	return depth
+}
+
+// GenerateProof returns the segment-sibling path proving that the chunk
+// hash recorded for (purl, offset) is included in the accumulator root.
+func (b *BMT) GenerateProof(preImage string) ([][]byte, *big.Int, error) {
+	purl, offset, err := parseBMTKey(preImage)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	value, ok := b.items[preImage]
+	if !ok {
+		return nil, nil, fmt.Errorf("no chunk hash recorded for %s", preImage)
+	}
+
+	byPurl, err := b.groupByPurl()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	purls := sortedPurls(byPurl)
+
+	var (
+		targetFileIndex = -1
+		targetSegments  [][]byte
+		targetSegIndex  int
+	)
+
+	fileRoots := make([][]byte, len(purls))
+	for i, p := range purls {
+		offsets := byPurl[p]
+		segments, err := b.segmentsForPurl(offsets)
+		if err != nil {
+			return nil, nil, err
+		}
+		fileRoots[i] = b.chainedChunkRoot(segments)
+
+		if p == purl {
+			targetFileIndex = i
+			targetSegments = segments
+			// segmentsForPurl sorts by offset, so the target's segment
+			// index is the number of offsets below it.
+			for _, ov := range offsets {
+				if ov.offset < offset {
+					targetSegIndex++
+				}
+			}
+		}
+	}
+
+	if targetFileIndex < 0 {
+		return nil, nil, fmt.Errorf("purl %s not found", purl)
+	}
+
+	siblings := b.chainedChunkPath(targetSegments, targetSegIndex)
+	siblings = append(siblings, merklePath(fileRoots, targetFileIndex, b.segmentSize)...)
+
+	return siblings, value, nil
+}
+
+func (b *BMT) groupByPurl() (map[string][]offsetValue, error) {
+	byPurl := make(map[string][]offsetValue)
+	for key, value := range b.items {
+		purl, offset, err := parseBMTKey(key)
+		if err != nil {
+			return nil, err
+		}
+		byPurl[purl] = append(byPurl[purl], offsetValue{offset, value})
+	}
+	return byPurl, nil
+}
+
+func sortedPurls(byPurl map[string][]offsetValue) []string {
+	purls := make([]string, 0, len(byPurl))
+	for purl := range byPurl {
+		purls = append(purls, purl)
+	}
+	sort.Strings(purls)
+	return purls
+}
+
+func (b *BMT) segmentsForPurl(offsets []offsetValue) ([][]byte, error) {
+	sorted := make([]offsetValue, len(offsets))
+	copy(sorted, offsets)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].offset < sorted[j].offset })
+
+	segments := make([][]byte, len(sorted))
+	for i, ov := range sorted {
+		seg, err := b.segmentBytes(ov.value)
+		if err != nil {
+			return nil, fmt.Errorf("segment at offset %d: %w", ov.offset, err)
+		}
+		segments[i] = seg
+	}
+	return segments, nil
+}
+
+// segmentBytes left-pads value into a fixed segmentSize-byte segment. It
+// errors rather than truncating when value doesn't fit, since silently
+// dropping high-order bytes would make the tree commit to a different
+// value than the one the caller supplied (e.g. a SHA-384/512 digest next
+// to SHA-256 in the same component's declared hashes). Callers with wider
+// values must split them into segmentSize-sized pieces before inserting.
+func (b *BMT) segmentBytes(value *big.Int) ([]byte, error) {
+	valBytes := value.Bytes()
+	if len(valBytes) > b.segmentSize {
+		return nil, fmt.Errorf("value is %d bytes, which doesn't fit in a %d-byte segment", len(valBytes), b.segmentSize)
+	}
+
+	buf := make([]byte, b.segmentSize)
+	copy(buf[b.segmentSize-len(valBytes):], valBytes)
+	return buf, nil
+}
+
+// chainedChunkRoot groups segments into segmentsPerChunk-sized chunks,
+// Merkle-roots each chunk, and recurses over the chunk roots until a
+// single file root remains.
+func (b *BMT) chainedChunkRoot(segments [][]byte) []byte {
+	if len(segments) == 0 {
+		segments = [][]byte{make([]byte, b.segmentSize)}
+	}
+
+	level := segments
+	for {
+		numChunks := (len(level) + b.segmentsPerChunk - 1) / b.segmentsPerChunk
+		if numChunks <= 1 {
+			return merkleRoot(level, b.segmentSize)
+		}
+
+		chunkRoots := make([][]byte, numChunks)
+		for c := range chunkRoots {
+			start := c * b.segmentsPerChunk
+			end := start + b.segmentsPerChunk
+			if end > len(level) {
+				end = len(level)
+			}
+			chunkRoots[c] = merkleRoot(level[start:end], b.segmentSize)
+		}
+		level = chunkRoots
+	}
+}
+
+// chainedChunkPath mirrors chainedChunkRoot but accumulates the sibling
+// path to the index-th segment instead of only the final root.
+func (b *BMT) chainedChunkPath(segments [][]byte, index int) [][]byte {
+	if len(segments) == 0 {
+		segments = [][]byte{make([]byte, b.segmentSize)}
+	}
+
+	var siblings [][]byte
+	level := segments
+	idx := index
+
+	for {
+		numChunks := (len(level) + b.segmentsPerChunk - 1) / b.segmentsPerChunk
+		chunkIdx := idx / b.segmentsPerChunk
+		within := idx % b.segmentsPerChunk
+
+		start := chunkIdx * b.segmentsPerChunk
+		end := start + b.segmentsPerChunk
+		if end > len(level) {
+			end = len(level)
+		}
+		siblings = append(siblings, merklePath(level[start:end], within, b.segmentSize)...)
+
+		if numChunks <= 1 {
+			return siblings
+		}
+
+		chunkRoots := make([][]byte, numChunks)
+		for c := range chunkRoots {
+			cs := c * b.segmentsPerChunk
+			ce := cs + b.segmentsPerChunk
+			if ce > len(level) {
+				ce = len(level)
+			}
+			chunkRoots[c] = merkleRoot(level[cs:ce], b.segmentSize)
+		}
+		level = chunkRoots
+		idx = chunkIdx
+	}
+}
+
+func hashSegmentPair(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+func padToPow2(leaves [][]byte, segmentSize int) [][]byte {
+	if len(leaves) == 0 {
+		return [][]byte{make([]byte, segmentSize)}
+	}
+
+	size := 1
+	for size < len(leaves) {
+		size *= 2
+	}
+
+	padded := make([][]byte, size)
+	copy(padded, leaves)
+	zero := make([]byte, segmentSize)
+	for i := len(leaves); i < size; i++ {
+		padded[i] = zero
+	}
+	return padded
+}
+
+func merkleRoot(leaves [][]byte, segmentSize int) []byte {
+	level := padToPow2(leaves, segmentSize)
+	for len(level) > 1 {
+		next := make([][]byte, len(level)/2)
+		for i := range next {
+			next[i] = hashSegmentPair(level[2*i], level[2*i+1])
+		}
+		level = next
+	}
+	return level[0]
+}
+
+func merklePath(leaves [][]byte, index int, segmentSize int) [][]byte {
+	level := padToPow2(leaves, segmentSize)
+
+	var siblings [][]byte
+	idx := index
+	for len(level) > 1 {
+		siblings = append(siblings, level[idx^1])
+
+		next := make([][]byte, len(level)/2)
+		for i := range next {
+			next[i] = hashSegmentPair(level[2*i], level[2*i+1])
+		}
+		level = next
+		idx /= 2
+	}
+	return siblings
+}
+
+type bmtJSON struct {
+	SegmentSize      int               `json:"segmentSize"`
+	SegmentsPerChunk int               `json:"segmentsPerChunk"`
+	Root             string            `json:"root"`
+	Depth            int               `json:"depth"`
+	Items            map[string]string `json:"items"`
+}
+
+func (b *BMT) MarshalJSON() ([]byte, error) {
+	items := make(map[string]string, len(b.items))
+	for k, v := range b.items {
+		items[k] = v.String()
+	}
+
+	return json.Marshal(bmtJSON{
+		SegmentSize:      b.segmentSize,
+		SegmentsPerChunk: b.segmentsPerChunk,
+		Root:             hex.EncodeToString(b.root),
+		Depth:            b.depth,
+		Items:            items,
+	})
+}
+
+func (b *BMT) UnmarshalJSON(data []byte) error {
+	var parsed bmtJSON
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return err
+	}
+
+	b.segmentSize = parsed.SegmentSize
+	b.segmentsPerChunk = parsed.SegmentsPerChunk
+	b.depth = parsed.Depth
+
+	root, err := hex.DecodeString(parsed.Root)
+	if err != nil {
+		return err
+	}
+	b.root = root
+
+	b.items = make(map[string]*big.Int, len(parsed.Items))
+	for k, valStr := range parsed.Items {
+		val, ok := new(big.Int).SetString(valStr, 10)
+		if !ok {
+			return fmt.Errorf("invalid big.Int: %s", valStr)
+		}
+		b.items[k] = val
+	}
+
+	return nil
+}
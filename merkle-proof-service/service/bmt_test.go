@@ -0,0 +1,178 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"sort"
+	"testing"
+)
+
+// reconstructBMTRoot replays a GenerateProof sibling path the same way
+// chainedChunkPath/merklePath produced it, to confirm the proof actually
+// commits to the accumulator root. It only covers the single-chunk case
+// (at most segmentsPerChunk segments per purl), which is all GenerateProof
+// needs to combine for the sizes exercised here.
+func reconstructBMTRoot(t *testing.T, segIndex, numSegments, fileIndex, numFiles int, value *big.Int, siblings [][]byte) []byte {
+	t.Helper()
+
+	b := &BMT{segmentSize: defaultSegmentSize, segmentsPerChunk: defaultSegmentsPerChunk}
+	current, err := b.segmentBytes(value)
+	if err != nil {
+		t.Fatalf("segmentBytes: %v", err)
+	}
+
+	i := 0
+	climb := func(idx, levelSize int) {
+		size := 1
+		for size < levelSize {
+			size *= 2
+		}
+		for size > 1 {
+			if i >= len(siblings) {
+				t.Fatalf("ran out of siblings reconstructing the proof")
+			}
+			sib := siblings[i]
+			i++
+			if idx%2 == 0 {
+				current = hashSegmentPair(current, sib)
+			} else {
+				current = hashSegmentPair(sib, current)
+			}
+			idx /= 2
+			size /= 2
+		}
+	}
+
+	climb(segIndex, numSegments)
+	climb(fileIndex, numFiles)
+
+	if i != len(siblings) {
+		t.Fatalf("used %d of %d siblings reconstructing the proof", i, len(siblings))
+	}
+
+	return current
+}
+
+// bmtProofIndices mirrors the sorting GenerateProof itself does internally
+// (purls alphabetically, offsets numerically) to recover the (fileIndex,
+// segIndex) pair a given (purl, offset) ends up at.
+func bmtProofIndices(t *testing.T, b *BMT, purl string, offset int64) (fileIndex, segIndex, numSegments, numFiles int) {
+	t.Helper()
+
+	byPurl, err := b.groupByPurl()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	purls := sortedPurls(byPurl)
+	numFiles = len(purls)
+	fileIndex = -1
+	for i, p := range purls {
+		if p == purl {
+			fileIndex = i
+		}
+	}
+	if fileIndex < 0 {
+		t.Fatalf("purl %s not found", purl)
+	}
+
+	offsets := append([]offsetValue(nil), byPurl[purl]...)
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i].offset < offsets[j].offset })
+	numSegments = len(offsets)
+	segIndex = -1
+	for i, ov := range offsets {
+		if ov.offset == offset {
+			segIndex = i
+		}
+	}
+	if segIndex < 0 {
+		t.Fatalf("offset %d not found for purl %s", offset, purl)
+	}
+
+	return fileIndex, segIndex, numSegments, numFiles
+}
+
+func TestBMTGenerateProofRoundTrip(t *testing.T) {
+	items := map[string]*big.Int{
+		bmtKey("pkg:generic/a@1.0.0", 0): big.NewInt(11),
+		bmtKey("pkg:generic/a@1.0.0", 1): big.NewInt(22),
+		bmtKey("pkg:generic/b@1.0.0", 0): big.NewInt(33),
+	}
+
+	b, err := newBMT()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := b.Build(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key := range items {
+		purl, offset, err := parseBMTKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		siblings, value, err := b.GenerateProof(key)
+		if err != nil {
+			t.Fatalf("GenerateProof(%s): %v", key, err)
+		}
+
+		fileIndex, segIndex, numSegments, numFiles := bmtProofIndices(t, b, purl, offset)
+		got := reconstructBMTRoot(t, segIndex, numSegments, fileIndex, numFiles, value, siblings)
+
+		if !bytes.Equal(got, root) {
+			t.Errorf("proof for %s did not reconstruct the accumulator root", key)
+		}
+	}
+}
+
+func TestBMTMarshalJSONReportsDepth(t *testing.T) {
+	b, err := newBMT()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Build(map[string]*big.Int{
+		bmtKey("pkg:generic/a@1.0.0", 0): big.NewInt(1),
+		bmtKey("pkg:generic/a@1.0.0", 1): big.NewInt(2),
+		bmtKey("pkg:generic/b@1.0.0", 0): big.NewInt(3),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := b.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var parsed bmtJSON
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.Depth == 0 {
+		t.Error("MarshalJSON reported depth 0 for a non-empty tree")
+	}
+	if parsed.Depth != b.depth {
+		t.Errorf("MarshalJSON depth = %d, want %d", parsed.Depth, b.depth)
+	}
+}
+
+func TestBMTGenerateProofUnknownKey(t *testing.T) {
+	b, err := newBMT()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Build(map[string]*big.Int{
+		bmtKey("pkg:generic/a@1.0.0", 0): big.NewInt(1),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := b.GenerateProof(bmtKey("pkg:generic/a@1.0.0", 1)); err == nil {
+		t.Error("GenerateProof for an offset that was never inserted should error")
+	}
+}
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"log"
 	"math/big"
@@ -16,6 +18,8 @@ func getExtractor(name string) (extractor, error) {
 	switch name {
 	case "dependency":
 		return &dependencyExtractor{}, nil
+	case "fileHash":
+		return &fileHashExtractor{}, nil
 	default:
 		return nil, fmt.Errorf("unknown extractor: %s", name)
 	}
@@ -43,3 +47,93 @@ func (e *dependencyExtractor) Extract(bom *cyclonedx.BOM) (map[string]*big.Int,
 	return items, nil
 }
 
+// fileHashExtractor commits to file content rather than mere presence, so a
+// proof can attest to a specific byte range of a specific artifact. It feeds
+// (purl, offset) -> chunkHash entries suitable for the "bmt" accumulator.
+//
+// When FetchFunc is set, the extractor downloads and chunks the artifact
+// itself into defaultChunkSize-byte pieces, one entry per chunk offset.
+// Otherwise it falls back to the hash(es) the BOM already declares in
+// component.Hashes, one entry per defaultSegmentSize-byte segment of each
+// declared hash, at offsets 0, 1, 2, ...
+type fileHashExtractor struct {
+	FetchFunc func(purl string) ([]byte, error)
+}
+
+func (e *fileHashExtractor) Extract(bom *cyclonedx.BOM) (map[string]*big.Int, error) {
+	items := make(map[string]*big.Int)
+
+	if bom.Components == nil {
+		return items, nil
+	}
+
+	for _, comp := range *bom.Components {
+		if comp.PackageURL == "" {
+			log.Printf("component %s has no PURL, skipping", comp.Name)
+			continue
+		}
+
+		if e.FetchFunc != nil {
+			if err := e.extractFromFetch(items, comp); err != nil {
+				log.Printf("fetching %s: %v, skipping", comp.PackageURL, err)
+			}
+			continue
+		}
+
+		e.extractFromDeclaredHashes(items, comp)
+	}
+
+	return items, nil
+}
+
+func (e *fileHashExtractor) extractFromFetch(items map[string]*big.Int, comp cyclonedx.Component) error {
+	data, err := e.FetchFunc(comp.PackageURL)
+	if err != nil {
+		return err
+	}
+
+	for offset := int64(0); int(offset)*defaultChunkSize < len(data); offset++ {
+		start := int(offset) * defaultChunkSize
+		end := start + defaultChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+
+		chunkHash := sha256.Sum256(data[start:end])
+		items[bmtKey(comp.PackageURL, offset)] = new(big.Int).SetBytes(chunkHash[:])
+	}
+
+	return nil
+}
+
+// extractFromDeclaredHashes feeds each declared hash in as consecutive
+// defaultSegmentSize-byte segments rather than one (possibly oversized)
+// segment per hash: CycloneDX commonly declares a SHA-384/512 digest
+// alongside SHA-256, and those don't fit in a single 32-byte BMT segment.
+// The offset counter runs across all of a component's declared hashes, so
+// every segment the component contributes gets a distinct, stable offset.
+func (e *fileHashExtractor) extractFromDeclaredHashes(items map[string]*big.Int, comp cyclonedx.Component) {
+	if comp.Hashes == nil || len(*comp.Hashes) == 0 {
+		log.Printf("component %s has no declared hashes, skipping", comp.Name)
+		return
+	}
+
+	var offset int64
+	for _, h := range *comp.Hashes {
+		raw, err := hex.DecodeString(h.Value)
+		if err != nil {
+			log.Printf("component %s has invalid hash %q, skipping", comp.Name, h.Value)
+			continue
+		}
+
+		for start := 0; start < len(raw); start += defaultSegmentSize {
+			end := start + defaultSegmentSize
+			if end > len(raw) {
+				end = len(raw)
+			}
+			items[bmtKey(comp.PackageURL, offset)] = new(big.Int).SetBytes(raw[start:end])
+			offset++
+		}
+	}
+}
+
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+// Hasher abstracts the leaf/node hash functions used by SMT, so proofs can
+// be produced with a hash that is cheap to re-verify inside a SNARK/STARK
+// circuit instead of always paying for a SHA-256 digest on-chain.
+type Hasher interface {
+	HashLeaf(val *big.Int) []byte
+	HashNode(left, right []byte) []byte
+	Size() int
+	Name() string
+}
+
+// keyHasher is implemented by hashers whose leaf path must be derived as a
+// field element rather than raw SHA-256 bytes, so that keys and internal
+// nodes live in the same domain.
+type keyHasher interface {
+	HashKey(preImage string) *big.Int
+}
+
+const defaultHasherName = "sha256"
+
+func getHasher(name string) (Hasher, error) {
+	switch name {
+	case "", defaultHasherName:
+		return &sha256Hasher{}, nil
+	case "poseidon":
+		return newPoseidonHasher(), nil
+	default:
+		return nil, fmt.Errorf("unknown hasher: %s", name)
+	}
+}
+
+type sha256Hasher struct{}
+
+func (h *sha256Hasher) HashLeaf(val *big.Int) []byte {
+	paddedBytes := make([]byte, HashSize)
+	valBytes := val.Bytes()
+	copy(paddedBytes[HashSize-len(valBytes):], valBytes)
+
+	sum := sha256.Sum256(paddedBytes)
+	return sum[:]
+}
+
+func (h *sha256Hasher) HashNode(left, right []byte) []byte {
+	hh := sha256.New()
+	hh.Write(left)
+	hh.Write(right)
+	return hh.Sum(nil)
+}
+
+func (h *sha256Hasher) Size() int {
+	return HashSize
+}
+
+func (h *sha256Hasher) Name() string {
+	return defaultHasherName
+}
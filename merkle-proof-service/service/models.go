@@ -1,6 +1,9 @@
 package service
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"math/big"
+)
 
 type BuildResult struct {
 	SMT   json.RawMessage
@@ -22,3 +25,9 @@ type BatchProofResult struct {
 	Proofs []ProofResult
 }
 
+// InsertOp is a single leaf upsert applied by SMTService.UpdateSMT.
+type InsertOp struct {
+	Purl  string
+	Value *big.Int
+}
+
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/iden3/go-iden3-crypto/poseidon"
+)
+
+const poseidonHashSize = 32
+
+// bn254FrModulus is the scalar field order of BN254, the curve Poseidon is
+// instantiated over here so proofs can be re-verified cheaply inside a
+// zk-SNARK circuit.
+var bn254FrModulus, _ = new(big.Int).SetString(
+	"21888242871839275222246405745257275088548364400416034343698204186575808495617", 10)
+
+type poseidonHasher struct{}
+
+func newPoseidonHasher() *poseidonHasher {
+	return &poseidonHasher{}
+}
+
+// HashLeaf reduces val into the BN254 scalar field before hashing, same as
+// HashKey does for preimages. Without this, a caller-supplied value at or
+// above the field modulus (e.g. an untrusted proof value from
+// /verify-batch) makes poseidon.Hash return an error, which hash turns into
+// a panic instead of producing a result.
+func (h *poseidonHasher) HashLeaf(val *big.Int) []byte {
+	reduced := new(big.Int).Mod(val, bn254FrModulus)
+	return h.hash(reduced)
+}
+
+func (h *poseidonHasher) HashNode(left, right []byte) []byte {
+	return h.hash(new(big.Int).SetBytes(left), new(big.Int).SetBytes(right))
+}
+
+func (h *poseidonHasher) Size() int {
+	return poseidonHashSize
+}
+
+func (h *poseidonHasher) Name() string {
+	return "poseidon"
+}
+
+// HashKey reduces a SHA-256 digest of preImage into the BN254 scalar field
+// so leaf paths live in the same domain as Poseidon's own outputs.
+func (h *poseidonHasher) HashKey(preImage string) *big.Int {
+	digest := sha256.Sum256([]byte(preImage))
+	raw := new(big.Int).SetBytes(digest[:])
+	return raw.Mod(raw, bn254FrModulus)
+}
+
+func (h *poseidonHasher) hash(inputs ...*big.Int) []byte {
+	sum, err := poseidon.Hash(inputs)
+	if err != nil {
+		panic(fmt.Sprintf("poseidon hash: %v", err))
+	}
+
+	buf := make([]byte, poseidonHashSize)
+	sum.FillBytes(buf)
+	return buf
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+func TestPoseidonHasherDeterministic(t *testing.T) {
+	h := newPoseidonHasher()
+
+	a := h.HashLeaf(big.NewInt(42))
+	b := h.HashLeaf(big.NewInt(42))
+	if !bytes.Equal(a, b) {
+		t.Error("HashLeaf is not deterministic for the same input")
+	}
+
+	c := h.HashLeaf(big.NewInt(43))
+	if bytes.Equal(a, c) {
+		t.Error("HashLeaf produced the same digest for different inputs")
+	}
+}
+
+// TestPoseidonHasherHashLeafReducesField asserts HashLeaf reduces its input
+// into the BN254 scalar field instead of passing it straight to
+// poseidon.Hash: a value at or above the modulus must hash the same as its
+// reduced form, and must not panic.
+func TestPoseidonHasherHashLeafReducesField(t *testing.T) {
+	h := newPoseidonHasher()
+
+	small := big.NewInt(7)
+	aboveModulus := new(big.Int).Add(bn254FrModulus, small)
+
+	gotSmall := h.HashLeaf(small)
+	gotAboveModulus := h.HashLeaf(aboveModulus)
+
+	if !bytes.Equal(gotSmall, gotAboveModulus) {
+		t.Error("HashLeaf(modulus + 7) != HashLeaf(7); HashLeaf isn't reducing into the scalar field")
+	}
+}
+
+// TestPoseidonHasherHashLeafNoPanicOnLargeValue reproduces the crash an
+// attacker-supplied /verify-batch value used to cause: a value many times
+// larger than the BN254 modulus previously made poseidon.Hash fail with
+// "inputs values not inside Finite Field", which hash turned into a panic
+// instead of returning.
+func TestPoseidonHasherHashLeafNoPanicOnLargeValue(t *testing.T) {
+	h := newPoseidonHasher()
+
+	huge := new(big.Int).Lsh(big.NewInt(1), 512)
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("HashLeaf panicked on an out-of-range value: %v", r)
+		}
+	}()
+
+	if digest := h.HashLeaf(huge); len(digest) != poseidonHashSize {
+		t.Errorf("HashLeaf(huge) returned %d bytes, want %d", len(digest), poseidonHashSize)
+	}
+}
+
+func TestPoseidonSMTBuildAndVerify(t *testing.T) {
+	items := map[string]*big.Int{
+		"pkg:generic/a@1.0.0": big.NewInt(1),
+		"pkg:generic/b@1.0.0": big.NewInt(2),
+	}
+
+	const depth = 32
+
+	smt, err := newSMTWithHasher(depth, newPoseidonHasher())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := smt.Build(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	siblings, value, err := smt.GenerateProof("pkg:generic/a@1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valid, err := NewSMTVerifier().Verify(root, "pkg:generic/a@1.0.0", value, siblings, nil, depth, "poseidon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !valid {
+		t.Error("poseidon-backed proof did not verify")
+	}
+}
@@ -5,19 +5,30 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"log"
+	"math"
 
 	"github.com/CycloneDX/cyclonedx-go"
 )
 
 type SMTService struct {
 	storage *Storage
+
+	// DisableParallel forces sequential SMT construction, useful for
+	// deterministic profiling and benchmarking.
+	DisableParallel bool
+
+	// ParallelThreshold overrides the subtree size above which SMT
+	// construction parallelizes. Zero means "use the accumulator's
+	// default" (defaultParallelThreshold). Ignored when DisableParallel
+	// is set.
+	ParallelThreshold int
 }
 
 func NewSMTService(storage *Storage) *SMTService {
 	return &SMTService{storage: storage}
 }
 
-func (s *SMTService) BuildSMT(bom *cyclonedx.BOM, extractorName, accumulatorName string) (*BuildResult, error) {
+func (s *SMTService) BuildSMT(bom *cyclonedx.BOM, extractorName, accumulatorName, hashName string) (*BuildResult, error) {
 	ex, err := getExtractor(extractorName)
 	if err != nil {
 		return nil, err
@@ -28,11 +39,20 @@ func (s *SMTService) BuildSMT(bom *cyclonedx.BOM, extractorName, accumulatorName
 		return nil, err
 	}
 
-	acc, err := getAccumulator(accumulatorName)
+	acc, err := getAccumulator(accumulatorName, hashName)
 	if err != nil {
 		return nil, err
 	}
 
+	if smtAcc, ok := acc.(*SMT); ok {
+		switch {
+		case s.DisableParallel:
+			smtAcc.parallelThreshold = math.MaxInt
+		case s.ParallelThreshold > 0:
+			smtAcc.parallelThreshold = s.ParallelThreshold
+		}
+	}
+
 	root, err := acc.Build(items)
 	if err != nil {
 		return nil, err
@@ -65,6 +85,57 @@ func (s *SMTService) GetSMT(rootHash string) ([]byte, error) {
 	return s.storage.GetSMT(rootHash)
 }
 
+// UpdateSMT loads the SMT stored under rootHash, applies inserts followed by
+// deletes, and stores the result under its new root without rebuilding the
+// tree from scratch.
+func (s *SMTService) UpdateSMT(rootHash string, inserts []InsertOp, deletes []string, accumulatorName string) (*BuildResult, error) {
+	smtData, err := s.storage.GetSMT(rootHash)
+	if err != nil {
+		return nil, err
+	}
+
+	acc, err := getAccumulator(accumulatorName, defaultHasherName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(smtData, acc); err != nil {
+		return nil, err
+	}
+
+	for _, ins := range inserts {
+		if _, err := acc.Insert(ins.Purl, ins.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, purl := range deletes {
+		if _, err := acc.Delete(purl); err != nil {
+			return nil, err
+		}
+	}
+
+	accData, err := json.Marshal(acc)
+	if err != nil {
+		return nil, err
+	}
+
+	var meta struct {
+		Depth int    `json:"depth"`
+		Root  string `json:"root"`
+	}
+	json.Unmarshal(accData, &meta)
+
+	if err := s.storage.StoreSMT(meta.Root, json.RawMessage(accData)); err != nil {
+		return nil, err
+	}
+
+	return &BuildResult{
+		Root:  meta.Root,
+		Depth: meta.Depth,
+	}, nil
+}
+
 func (s *SMTService) GenerateBatchProofs(rootHash string, purls []string, compress bool, accumulatorName string) (*BatchProofResult, error) {
 	// Fetch SMT from storage
 	smtData, err := s.storage.GetSMT(rootHash)
@@ -72,7 +143,7 @@ func (s *SMTService) GenerateBatchProofs(rootHash string, purls []string, compre
 		return nil, err
 	}
 
-	acc, err := getAccumulator(accumulatorName)
+	acc, err := getAccumulator(accumulatorName, defaultHasherName)
 	if err != nil {
 		return nil, err
 	}
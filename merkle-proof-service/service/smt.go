@@ -8,21 +8,29 @@ import (
 	"fmt"
 	"math/big"
 	"sort"
+	"sync"
 )
 
 const HashSize = 32
 
+// defaultParallelThreshold is the subtree size above which buildRecursive
+// dispatches its two child computations onto separate goroutines.
+const defaultParallelThreshold = 100
+
 type node struct {
 	Left  []byte
 	Right []byte
 }
 
 type SMT struct {
-	depth         int
-	defaultHashes [][]byte
-	root          []byte
-	nodes         map[string]node
-	leaves        map[string]*big.Int
+	depth             int
+	defaultHashes     [][]byte
+	root              []byte
+	nodes             map[string]node
+	leaves            map[string]*big.Int
+	parallelThreshold int
+	hasher            Hasher
+	mu                sync.Mutex
 }
 
 type smtItem struct {
@@ -31,17 +39,23 @@ type smtItem struct {
 }
 
 func newSMT(depth int) (*SMT, error) {
+	return newSMTWithHasher(depth, &sha256Hasher{})
+}
+
+func newSMTWithHasher(depth int, hasher Hasher) (*SMT, error) {
 	smt := &SMT{
-		depth:         depth,
-		defaultHashes: make([][]byte, depth+1),
-		nodes:         make(map[string]node),
-		leaves:        make(map[string]*big.Int),
+		depth:             depth,
+		defaultHashes:     make([][]byte, depth+1),
+		nodes:             make(map[string]node),
+		leaves:            make(map[string]*big.Int),
+		parallelThreshold: defaultParallelThreshold,
+		hasher:            hasher,
 	}
 
-	smt.defaultHashes[0] = hashLeaf(big.NewInt(0))
+	smt.defaultHashes[0] = hasher.HashLeaf(big.NewInt(0))
 
 	for i := 1; i <= depth; i++ {
-		smt.defaultHashes[i] = hashNode(smt.defaultHashes[i-1], smt.defaultHashes[i-1])
+		smt.defaultHashes[i] = hasher.HashNode(smt.defaultHashes[i-1], smt.defaultHashes[i-1])
 	}
 
 	return smt, nil
@@ -55,9 +69,7 @@ func (s *SMT) Build(items map[string]*big.Int) ([]byte, error) {
 
 	smtItems := make([]smtItem, 0, len(items))
 	for preImage, value := range items {
-		keyHash := sha256.Sum256([]byte(preImage))
-		path := new(big.Int).SetBytes(keyHash[:])
-		smtItems = append(smtItems, smtItem{path, value})
+		smtItems = append(smtItems, smtItem{s.keyPath(preImage), value})
 	}
 
 	sort.Slice(smtItems, func(i, j int) bool {
@@ -80,8 +92,12 @@ func (s *SMT) buildRecursive(depth int, items []smtItem) ([]byte, error) {
 
 	if depth == s.depth {
 		leaf := items[0]
+
+		s.mu.Lock()
 		s.leaves[leaf.path.String()] = leaf.value
-		return hashLeaf(leaf.value), nil
+		s.mu.Unlock()
+
+		return s.hasher.HashLeaf(leaf.value), nil
 	}
 
 	bitIndex := s.depth - 1 - depth
@@ -93,17 +109,40 @@ func (s *SMT) buildRecursive(depth int, items []smtItem) ([]byte, error) {
 	leftItems := items[:splitIndex]
 	rightItems := items[splitIndex:]
 
-	leftHash, err := s.buildRecursive(depth+1, leftItems)
-	if err != nil {
-		return nil, err
+	var leftHash, rightHash []byte
+	var leftErr, rightErr error
+
+	if len(items) > s.parallelThreshold {
+		var wg sync.WaitGroup
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+			leftHash, leftErr = s.buildRecursive(depth+1, leftItems)
+		}()
+		go func() {
+			defer wg.Done()
+			rightHash, rightErr = s.buildRecursive(depth+1, rightItems)
+		}()
+
+		wg.Wait()
+	} else {
+		leftHash, leftErr = s.buildRecursive(depth+1, leftItems)
+		rightHash, rightErr = s.buildRecursive(depth+1, rightItems)
 	}
-	rightHash, err := s.buildRecursive(depth+1, rightItems)
-	if err != nil {
-		return nil, err
+
+	if leftErr != nil {
+		return nil, leftErr
 	}
+	if rightErr != nil {
+		return nil, rightErr
+	}
+
+	parentHash := s.hasher.HashNode(leftHash, rightHash)
 
-	parentHash := hashNode(leftHash, rightHash)
+	s.mu.Lock()
 	s.nodes[hex.EncodeToString(parentHash)] = node{Left: leftHash, Right: rightHash}
+	s.mu.Unlock()
 
 	return parentHash, nil
 }
@@ -139,6 +178,84 @@ func (s *SMT) GenerateProof(preImage string) ([][]byte, *big.Int, error) {
 	return siblings, value, nil
 }
 
+// Insert sets the leaf for preImage to value and returns the new root,
+// rehashing only the path from that leaf to the root instead of rebuilding
+// the whole tree.
+func (s *SMT) Insert(preImage string, value *big.Int) ([]byte, error) {
+	path, _ := s.getPathAndValue(preImage)
+	return s.update(path, value)
+}
+
+// Delete removes preImage's leaf (by setting it back to the default value)
+// and returns the new root.
+func (s *SMT) Delete(preImage string) ([]byte, error) {
+	path, _ := s.getPathAndValue(preImage)
+	return s.update(path, big.NewInt(0))
+}
+
+// update walks from the root along path's bits, materializing default nodes
+// into concrete entries only where the path diverges, rehashes along that
+// path, and prunes any node that becomes fully default so s.nodes stays
+// bounded.
+func (s *SMT) update(path *big.Int, value *big.Int) ([]byte, error) {
+	newRoot, err := s.updateRecursive(0, s.root, path, value)
+	if err != nil {
+		return nil, err
+	}
+	s.root = newRoot
+
+	if value.Sign() == 0 {
+		delete(s.leaves, path.String())
+	} else {
+		s.leaves[path.String()] = value
+	}
+
+	return s.root, nil
+}
+
+func (s *SMT) updateRecursive(depth int, currentHash []byte, path *big.Int, value *big.Int) ([]byte, error) {
+	if depth == s.depth {
+		return s.hasher.HashLeaf(value), nil
+	}
+
+	childDefault := s.defaultHashes[s.depth-depth-1]
+
+	oldKey := hex.EncodeToString(currentHash)
+	n, isNode := s.nodes[oldKey]
+
+	left, right := childDefault, childDefault
+	if isNode {
+		left, right = n.Left, n.Right
+	} else if !bytes.Equal(currentHash, s.defaultHashes[s.depth-depth]) {
+		return nil, fmt.Errorf("tree inconsistent at depth %d", depth)
+	}
+
+	bitIndex := s.depth - 1 - depth
+
+	var err error
+	if path.Bit(bitIndex) == 0 {
+		left, err = s.updateRecursive(depth+1, left, path, value)
+	} else {
+		right, err = s.updateRecursive(depth+1, right, path, value)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if isNode {
+		delete(s.nodes, oldKey)
+	}
+
+	if bytes.Equal(left, childDefault) && bytes.Equal(right, childDefault) {
+		return s.defaultHashes[s.depth-depth], nil
+	}
+
+	parentHash := s.hasher.HashNode(left, right)
+	s.nodes[hex.EncodeToString(parentHash)] = node{Left: left, Right: right}
+
+	return parentHash, nil
+}
+
 func (s *SMT) CompressProof(siblings [][]byte) ([][]byte, []byte, error) {
 	if len(siblings) != s.depth {
 		return nil, nil, fmt.Errorf("incorrect number of siblings")
@@ -160,8 +277,7 @@ func (s *SMT) CompressProof(siblings [][]byte) ([][]byte, []byte, error) {
 }
 
 func (s *SMT) getPathAndValue(preImage string) (*big.Int, *big.Int) {
-	keyHash := sha256.Sum256([]byte(preImage))
-	path := new(big.Int).SetBytes(keyHash[:])
+	path := s.keyPath(preImage)
 
 	value, ok := s.leaves[path.String()]
 	if !ok {
@@ -170,29 +286,25 @@ func (s *SMT) getPathAndValue(preImage string) (*big.Int, *big.Int) {
 	return path, value
 }
 
-func hashLeaf(val *big.Int) []byte {
-	paddedBytes := make([]byte, HashSize)
-	valBytes := val.Bytes()
-	copy(paddedBytes[HashSize-len(valBytes):], valBytes)
-
-	h := sha256.New()
-	h.Write(paddedBytes)
-	return h.Sum(nil)
-}
+// keyPath derives preImage's position in the tree. Hashers that need keys
+// and internal nodes to live in the same field domain (e.g. Poseidon) can
+// implement keyHasher; others fall back to raw SHA-256 bytes.
+func (s *SMT) keyPath(preImage string) *big.Int {
+	if kh, ok := s.hasher.(keyHasher); ok {
+		return kh.HashKey(preImage)
+	}
 
-func hashNode(left, right []byte) []byte {
-	h := sha256.New()
-	h.Write(left)
-	h.Write(right)
-	return h.Sum(nil)
+	keyHash := sha256.Sum256([]byte(preImage))
+	return new(big.Int).SetBytes(keyHash[:])
 }
 
 type smtJSON struct {
-	Depth         int                    `json:"depth"`
-	DefaultHashes []string               `json:"defaultHashes"`
-	Root          string                 `json:"root"`
-	Nodes         map[string]nodeJSON    `json:"nodes"`
-	Leaves        map[string]string      `json:"leaves"`
+	Depth         int                 `json:"depth"`
+	DefaultHashes []string            `json:"defaultHashes"`
+	Root          string              `json:"root"`
+	Nodes         map[string]nodeJSON `json:"nodes"`
+	Leaves        map[string]string   `json:"leaves"`
+	Hasher        string              `json:"hasher"`
 }
 
 type nodeJSON struct {
@@ -225,6 +337,7 @@ func (s *SMT) MarshalJSON() ([]byte, error) {
 		Root:          hex.EncodeToString(s.root),
 		Nodes:         nodes,
 		Leaves:        leaves,
+		Hasher:        s.hasher.Name(),
 	}
 
 	return json.Marshal(data)
@@ -236,6 +349,12 @@ func (s *SMT) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	hasher, err := getHasher(parsed.Hasher)
+	if err != nil {
+		return err
+	}
+	s.hasher = hasher
+
 	s.depth = parsed.Depth
 
 	s.defaultHashes = make([][]byte, len(parsed.DefaultHashes))
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func benchmarkItems(n int) map[string]*big.Int {
+	items := make(map[string]*big.Int, n)
+	for i := 0; i < n; i++ {
+		items[fmt.Sprintf("pkg:generic/bench-%d@1.0.0", i)] = big.NewInt(1)
+	}
+	return items
+}
+
+// benchmarkSizes are the item counts built at each benchmark point. The
+// depth-256 SMT retains on the order of items*depth node entries, so the
+// 100k point alone needs several GB of RSS — skip it under -short (e.g.
+// in CI or on a small dev machine) rather than risk an OOM kill.
+var benchmarkSizes = []int{1000, 10000, 100000}
+
+func benchmarkSizesFor(b *testing.B) []int {
+	if testing.Short() {
+		return benchmarkSizes[:2]
+	}
+	return benchmarkSizes
+}
+
+// BenchmarkSMTBuildSequential forces parallelThreshold above any subtree
+// size so buildRecursive never dispatches onto goroutines, giving a
+// baseline to compare against BenchmarkSMTBuildParallel.
+func BenchmarkSMTBuildSequential(b *testing.B) {
+	for _, n := range benchmarkSizesFor(b) {
+		items := benchmarkItems(n)
+		b.Run(fmt.Sprintf("%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				smt, err := newSMT(256)
+				if err != nil {
+					b.Fatal(err)
+				}
+				smt.parallelThreshold = 1 << 30
+				if _, err := smt.Build(items); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSMTBuildParallel(b *testing.B) {
+	for _, n := range benchmarkSizesFor(b) {
+		items := benchmarkItems(n)
+		b.Run(fmt.Sprintf("%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				smt, err := newSMT(256)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if _, err := smt.Build(items); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
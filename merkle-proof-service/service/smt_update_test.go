@@ -0,0 +1,119 @@
+package service
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+// TestSMTInsertMatchesRebuild asserts that incrementally Insert-ing items
+// one at a time produces the same root as Build-ing them all at once, so
+// the path-rehashing in update/updateRecursive stays consistent with the
+// from-scratch construction in buildRecursive.
+func TestSMTInsertMatchesRebuild(t *testing.T) {
+	items := map[string]*big.Int{
+		"pkg:generic/a@1.0.0": big.NewInt(1),
+		"pkg:generic/b@1.0.0": big.NewInt(2),
+		"pkg:generic/c@1.0.0": big.NewInt(3),
+	}
+
+	const depth = 32
+
+	built, err := newSMT(depth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRoot, err := built.Build(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inserted, err := newSMT(depth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := inserted.Build(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var gotRoot []byte
+	for purl, value := range items {
+		gotRoot, err = inserted.Insert(purl, value)
+		if err != nil {
+			t.Fatalf("Insert(%s): %v", purl, err)
+		}
+	}
+
+	if !bytes.Equal(gotRoot, wantRoot) {
+		t.Errorf("incremental Insert root = %x, want %x (matching Build)", gotRoot, wantRoot)
+	}
+}
+
+// TestSMTDeletePrunesBackToDefault asserts that deleting every inserted
+// item returns the tree to the same root as an empty build, so
+// updateRecursive's node pruning doesn't leave stale entries behind that
+// happen to not affect the root of this particular test but would corrupt a
+// later Insert's path-walk.
+func TestSMTDeletePrunesBackToDefault(t *testing.T) {
+	const depth = 32
+
+	smt, err := newSMT(depth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	empty, err := newSMT(depth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	emptyRoot, err := empty.Build(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := smt.Build(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	purls := []string{"pkg:generic/a@1.0.0", "pkg:generic/b@1.0.0", "pkg:generic/c@1.0.0"}
+	for i, purl := range purls {
+		if _, err := smt.Insert(purl, big.NewInt(int64(i+1))); err != nil {
+			t.Fatalf("Insert(%s): %v", purl, err)
+		}
+	}
+
+	var root []byte
+	for _, purl := range purls {
+		root, err = smt.Delete(purl)
+		if err != nil {
+			t.Fatalf("Delete(%s): %v", purl, err)
+		}
+	}
+
+	if !bytes.Equal(root, emptyRoot) {
+		t.Errorf("root after deleting every item = %x, want %x (empty tree)", root, emptyRoot)
+	}
+	if len(smt.nodes) != 0 {
+		t.Errorf("nodes map has %d leftover entries after deleting every item, want 0", len(smt.nodes))
+	}
+	if len(smt.leaves) != 0 {
+		t.Errorf("leaves map has %d leftover entries after deleting every item, want 0", len(smt.leaves))
+	}
+
+	// The tree must still be provable and verifiable after the insert/delete
+	// churn, not just structurally empty.
+	if _, err := smt.Insert("pkg:generic/a@1.0.0", big.NewInt(1)); err != nil {
+		t.Fatalf("Insert after full delete: %v", err)
+	}
+	siblings, value, err := smt.GenerateProof("pkg:generic/a@1.0.0")
+	if err != nil {
+		t.Fatalf("GenerateProof after full delete: %v", err)
+	}
+	valid, err := NewSMTVerifier().Verify(smt.root, "pkg:generic/a@1.0.0", value, siblings, nil, depth, "sha256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !valid {
+		t.Error("proof after full delete + reinsert did not verify")
+	}
+}
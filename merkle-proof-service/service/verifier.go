@@ -0,0 +1,91 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+)
+
+// Verifier checks a Merkle proof against a trusted root without needing the
+// full accumulator state, so a downstream consumer can validate proofs
+// without re-fetching or trusting the service that produced them.
+type Verifier interface {
+	Verify(root []byte, purl string, value *big.Int, siblings [][]byte, bitmap []byte, depth int, hashName string) (bool, error)
+}
+
+// SMTVerifier verifies proofs produced by SMT.GenerateProof/CompressProof.
+// It only understands the sparse-tree/default-hash scheme and cannot check
+// proofs from the "bmt" accumulator, which has no bitmap/default-hash
+// concept; callers must reject bmt before reaching this verifier.
+type SMTVerifier struct{}
+
+func NewSMTVerifier() *SMTVerifier {
+	return &SMTVerifier{}
+}
+
+func (v *SMTVerifier) Verify(root []byte, purl string, value *big.Int, siblings [][]byte, bitmap []byte, depth int, hashName string) (bool, error) {
+	hasher, err := getHasher(hashName)
+	if err != nil {
+		return false, err
+	}
+
+	smt, err := newSMTWithHasher(depth, hasher)
+	if err != nil {
+		return false, err
+	}
+
+	expanded := siblings
+	if len(bitmap) > 0 {
+		expanded, err = expandSiblings(siblings, bitmap, depth, smt.defaultHashes)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if len(expanded) != depth {
+		return false, fmt.Errorf("expected %d siblings, got %d", depth, len(expanded))
+	}
+
+	path := smt.keyPath(purl)
+
+	currentHash := hasher.HashLeaf(value)
+	for d := 0; d < depth; d++ {
+		if path.Bit(d) == 0 {
+			currentHash = hasher.HashNode(currentHash, expanded[d])
+		} else {
+			currentHash = hasher.HashNode(expanded[d], currentHash)
+		}
+	}
+
+	return bytes.Equal(currentHash, root), nil
+}
+
+// expandSiblings reconstructs the full depth-entry sibling list from a
+// compressed proof by filling in defaultHashes wherever bitmap marks the
+// sibling at that depth as the default one.
+func expandSiblings(compressed [][]byte, bitmap []byte, depth int, defaultHashes [][]byte) ([][]byte, error) {
+	expanded := make([][]byte, depth)
+	idx := 0
+
+	for d := 0; d < depth; d++ {
+		byteIdx := d / 8
+		bitIdx := uint(d % 8)
+
+		if byteIdx >= len(bitmap) {
+			return nil, fmt.Errorf("bitmap too short for depth %d", depth)
+		}
+
+		if bitmap[byteIdx]&(1<<bitIdx) == 0 {
+			expanded[d] = defaultHashes[d]
+			continue
+		}
+
+		if idx >= len(compressed) {
+			return nil, fmt.Errorf("not enough siblings for bitmap")
+		}
+		expanded[d] = compressed[idx]
+		idx++
+	}
+
+	return expanded, nil
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestSMTVerifierRoundTrip(t *testing.T) {
+	items := map[string]*big.Int{
+		"pkg:generic/a@1.0.0": big.NewInt(11),
+		"pkg:generic/b@1.0.0": big.NewInt(22),
+		"pkg:generic/c@1.0.0": big.NewInt(33),
+	}
+
+	const depth = 16
+
+	smt, err := newSMT(depth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := smt.Build(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifier := NewSMTVerifier()
+
+	for purl, value := range items {
+		siblings, gotValue, err := smt.GenerateProof(purl)
+		if err != nil {
+			t.Fatalf("GenerateProof(%s): %v", purl, err)
+		}
+		if gotValue.Cmp(value) != 0 {
+			t.Fatalf("GenerateProof(%s) value = %s, want %s", purl, gotValue, value)
+		}
+
+		valid, err := verifier.Verify(root, purl, gotValue, siblings, nil, depth, "sha256")
+		if err != nil {
+			t.Fatalf("Verify(%s): %v", purl, err)
+		}
+		if !valid {
+			t.Errorf("Verify(%s) = false, want true", purl)
+		}
+	}
+
+	// A proof for the wrong purl must not verify against the same root.
+	siblings, value, err := smt.GenerateProof("pkg:generic/a@1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid, err := verifier.Verify(root, "pkg:generic/not-present@1.0.0", value, siblings, nil, depth, "sha256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valid {
+		t.Error("Verify succeeded for a purl whose proof it wasn't generated for")
+	}
+}
+
+func TestSMTVerifierCompressedProofRoundTrip(t *testing.T) {
+	items := map[string]*big.Int{
+		"pkg:generic/a@1.0.0": big.NewInt(1),
+	}
+
+	const depth = 64
+
+	smt, err := newSMT(depth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := smt.Build(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	siblings, value, err := smt.GenerateProof("pkg:generic/a@1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compressed, bitmap, err := smt.CompressProof(siblings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packedBitmap := packBitmapBits(bitmap)
+
+	verifier := NewSMTVerifier()
+	valid, err := verifier.Verify(root, "pkg:generic/a@1.0.0", value, compressed, packedBitmap, depth, "sha256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !valid {
+		t.Error("Verify of compressed proof = false, want true")
+	}
+}